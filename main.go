@@ -2,19 +2,30 @@ package main
 
 import (
 	"fmt"
-	"io/ioutil"
-    "os"
+	"os"
 
 	"./nbtparser"
 )
 
 func main() {
-	buffer, err := ioutil.ReadFile(os.Args[1])
+	file, err := os.Open(os.Args[1])
 	if err != nil {
 		fmt.Print(err)
+		return
+	}
+	defer file.Close()
+
+	var result nbtparser.NamedTag
+	if os.Args[2] == "true" {
+		result, err = nbtparser.DecodeCompressed(file)
+	} else {
+		result, err = nbtparser.Decode(file)
+	}
+	if err != nil {
+		fmt.Print(err)
+		return
 	}
 
-	result := nbtparser.ParseNBT(buffer, os.Args[2]=="true")
 	var strBuffer string
 	result.Print(&strBuffer, "")
 	fmt.Print(strBuffer)
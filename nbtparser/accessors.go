@@ -0,0 +1,130 @@
+package nbtparser
+
+// getChild looks up key within tag's TAG_Compound payload.
+func (tag NamedTag) getChild(key string) (NamedTag, bool) {
+	compound, ok := tag.Payload.(TagCompound)
+	if !ok {
+		return NamedTag{}, false
+	}
+	return compound.Get(key)
+}
+
+// GetCompound returns the TAG_Compound child named key.
+func (tag NamedTag) GetCompound(key string) (TagCompound, bool) {
+	child, ok := tag.getChild(key)
+	if !ok {
+		return TagCompound{}, false
+	}
+	compound, ok := child.Payload.(TagCompound)
+	return compound, ok
+}
+
+// GetList returns the TAG_List child named key.
+func (tag NamedTag) GetList(key string) (ListTag, bool) {
+	child, ok := tag.getChild(key)
+	if !ok {
+		return ListTag{}, false
+	}
+	list, ok := child.Payload.(ListTag)
+	return list, ok
+}
+
+// GetByte returns the TAG_Byte child named key.
+func (tag NamedTag) GetByte(key string) (byte, bool) {
+	child, ok := tag.getChild(key)
+	if !ok {
+		return 0, false
+	}
+	v, ok := child.Payload.(byte)
+	return v, ok
+}
+
+// GetShort returns the TAG_Short child named key.
+func (tag NamedTag) GetShort(key string) (int16, bool) {
+	child, ok := tag.getChild(key)
+	if !ok {
+		return 0, false
+	}
+	v, ok := child.Payload.(int16)
+	return v, ok
+}
+
+// GetInt returns the TAG_Int child named key.
+func (tag NamedTag) GetInt(key string) (int32, bool) {
+	child, ok := tag.getChild(key)
+	if !ok {
+		return 0, false
+	}
+	v, ok := child.Payload.(int32)
+	return v, ok
+}
+
+// GetLong returns the TAG_Long child named key.
+func (tag NamedTag) GetLong(key string) (int64, bool) {
+	child, ok := tag.getChild(key)
+	if !ok {
+		return 0, false
+	}
+	v, ok := child.Payload.(int64)
+	return v, ok
+}
+
+// GetFloat returns the TAG_Float child named key.
+func (tag NamedTag) GetFloat(key string) (float32, bool) {
+	child, ok := tag.getChild(key)
+	if !ok {
+		return 0, false
+	}
+	v, ok := child.Payload.(float32)
+	return v, ok
+}
+
+// GetDouble returns the TAG_Double child named key.
+func (tag NamedTag) GetDouble(key string) (float64, bool) {
+	child, ok := tag.getChild(key)
+	if !ok {
+		return 0, false
+	}
+	v, ok := child.Payload.(float64)
+	return v, ok
+}
+
+// GetString returns the TAG_String child named key.
+func (tag NamedTag) GetString(key string) (string, bool) {
+	child, ok := tag.getChild(key)
+	if !ok {
+		return "", false
+	}
+	v, ok := child.Payload.(string)
+	return v, ok
+}
+
+// GetByteArray returns the TAG_Byte_Array child named key.
+func (tag NamedTag) GetByteArray(key string) ([]byte, bool) {
+	child, ok := tag.getChild(key)
+	if !ok {
+		return nil, false
+	}
+	v, ok := child.Payload.([]byte)
+	return v, ok
+}
+
+// GetIntArray returns the TAG_Int_Array child named key.
+func (tag NamedTag) GetIntArray(key string) ([]int32, bool) {
+	child, ok := tag.getChild(key)
+	if !ok {
+		return nil, false
+	}
+	v, ok := child.Payload.([]int32)
+	return v, ok
+}
+
+// GetLongArray returns the TAG_Long_Array child named key.
+func (tag NamedTag) GetLongArray(key string) ([]int64, bool) {
+	child, ok := tag.getChild(key)
+	if !ok {
+		return nil, false
+	}
+	v, ok := child.Payload.([]int64)
+	return v, ok
+}
@@ -0,0 +1,398 @@
+package nbtparser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// fieldTag is the parsed form of a struct field's `nbt:"name,type"` tag.
+type fieldTag struct {
+	name     string
+	typeHint string
+	skip     bool
+}
+
+func parseFieldTag(field reflect.StructField) fieldTag {
+	tagStr, ok := field.Tag.Lookup("nbt")
+	if !ok {
+		return fieldTag{name: field.Name}
+	}
+	if tagStr == "-" {
+		return fieldTag{skip: true}
+	}
+	parts := strings.SplitN(tagStr, ",", 2)
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	typeHint := ""
+	if len(parts) > 1 {
+		typeHint = parts[1]
+	}
+	return fieldTag{name: name, typeHint: typeHint}
+}
+
+// Marshal reflects over v (a struct, map, slice, or primitive) and converts it
+// to a NamedTag, the way encoding/json.Marshal converts Go values to JSON.
+// Struct fields may use an `nbt:"name,type"` tag to rename the key, override
+// an ambiguous numeric mapping (byte/short/int/long/float/double), or skip
+// the field entirely with `nbt:"-"`.
+func Marshal(v interface{}) (NamedTag, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return NamedTag{}, fmt.Errorf("nbtparser: cannot marshal untyped nil")
+	}
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return NamedTag{}, fmt.Errorf("nbtparser: cannot marshal nil pointer")
+		}
+		rv = rv.Elem()
+	}
+	payload, tagType, err := marshalValue(rv, "")
+	if err != nil {
+		return NamedTag{}, err
+	}
+	return NamedTag{TagType: tagType, Name: "", Payload: payload}, nil
+}
+
+func marshalValue(rv reflect.Value, typeHint string) (Tag, TagType, error) {
+	if !rv.IsValid() {
+		return nil, 0, fmt.Errorf("nbtparser: cannot marshal invalid value")
+	}
+
+	isUnsigned := rv.Kind() == reflect.Uint8 || rv.Kind() == reflect.Uint16 ||
+		rv.Kind() == reflect.Uint32 || rv.Kind() == reflect.Uint || rv.Kind() == reflect.Uint64
+	switch typeHint {
+	case "byte":
+		if isUnsigned {
+			return byte(rv.Uint()), TAG_Byte, nil
+		}
+		return byte(rv.Int()), TAG_Byte, nil
+	case "short":
+		if isUnsigned {
+			return int16(rv.Uint()), TAG_Short, nil
+		}
+		return int16(rv.Int()), TAG_Short, nil
+	case "int":
+		if isUnsigned {
+			return int32(rv.Uint()), TAG_Int, nil
+		}
+		return int32(rv.Int()), TAG_Int, nil
+	case "long":
+		if isUnsigned {
+			return int64(rv.Uint()), TAG_Long, nil
+		}
+		return rv.Int(), TAG_Long, nil
+	case "float":
+		return float32(rv.Float()), TAG_Float, nil
+	case "double":
+		return rv.Float(), TAG_Double, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			return byte(1), TAG_Byte, nil
+		}
+		return byte(0), TAG_Byte, nil
+	case reflect.Int8:
+		return byte(rv.Int()), TAG_Byte, nil
+	case reflect.Uint8:
+		return byte(rv.Uint()), TAG_Byte, nil
+	case reflect.Int16, reflect.Uint16:
+		if isUnsigned {
+			return int16(rv.Uint()), TAG_Short, nil
+		}
+		return int16(rv.Int()), TAG_Short, nil
+	case reflect.Int32, reflect.Int, reflect.Uint32, reflect.Uint:
+		if isUnsigned {
+			return int32(rv.Uint()), TAG_Int, nil
+		}
+		return int32(rv.Int()), TAG_Int, nil
+	case reflect.Int64, reflect.Uint64:
+		if isUnsigned {
+			return int64(rv.Uint()), TAG_Long, nil
+		}
+		return rv.Int(), TAG_Long, nil
+	case reflect.Float32:
+		return float32(rv.Float()), TAG_Float, nil
+	case reflect.Float64:
+		return rv.Float(), TAG_Double, nil
+	case reflect.String:
+		return rv.String(), TAG_String, nil
+	case reflect.Slice, reflect.Array:
+		return marshalSlice(rv)
+	case reflect.Map:
+		return marshalMap(rv)
+	case reflect.Struct:
+		return marshalStruct(rv)
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil, TAG_End, nil
+		}
+		return marshalValue(rv.Elem(), typeHint)
+	default:
+		return nil, 0, fmt.Errorf("nbtparser: cannot marshal type %s", rv.Type())
+	}
+}
+
+func marshalSlice(rv reflect.Value) (Tag, TagType, error) {
+	switch rv.Type().Elem().Kind() {
+	case reflect.Uint8:
+		payload := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(payload), rv)
+		return payload, TAG_Byte_Array, nil
+	case reflect.Int32:
+		payload := make([]int32, rv.Len())
+		for i := range payload {
+			payload[i] = int32(rv.Index(i).Int())
+		}
+		return payload, TAG_Int_Array, nil
+	case reflect.Int64:
+		payload := make([]int64, rv.Len())
+		for i := range payload {
+			payload[i] = rv.Index(i).Int()
+		}
+		return payload, TAG_Long_Array, nil
+	}
+
+	elements := make([]Tag, rv.Len())
+	listType := TagType(TAG_End)
+	for i := range elements {
+		payload, tagType, err := marshalValue(rv.Index(i), "")
+		if err != nil {
+			return nil, 0, err
+		}
+		if i == 0 {
+			listType = tagType
+		}
+		elements[i] = payload
+	}
+	return NewListTag(listType, elements), TAG_List, nil
+}
+
+func marshalMap(rv reflect.Value) (Tag, TagType, error) {
+	if rv.Type().Key().Kind() != reflect.String {
+		return nil, 0, fmt.Errorf("nbtparser: cannot marshal map with non-string key type %s", rv.Type().Key())
+	}
+	compound := NewTagCompound()
+	for _, key := range rv.MapKeys() {
+		payload, tagType, err := marshalValue(rv.MapIndex(key), "")
+		if err != nil {
+			return nil, 0, err
+		}
+		if tagType == TAG_End {
+			continue // nil pointer: omit rather than injecting a stray TAG_End entry
+		}
+		name := key.String()
+		compound.Set(name, NamedTag{TagType: tagType, Name: name, Payload: payload})
+	}
+	return compound, TAG_Compound, nil
+}
+
+func marshalStruct(rv reflect.Value) (Tag, TagType, error) {
+	t := rv.Type()
+	compound := NewTagCompound()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		ft := parseFieldTag(field)
+		if ft.skip {
+			continue
+		}
+		payload, tagType, err := marshalValue(rv.Field(i), ft.typeHint)
+		if err != nil {
+			return nil, 0, err
+		}
+		if tagType == TAG_End {
+			continue // nil pointer field: omit rather than injecting a stray TAG_End entry
+		}
+		compound.Set(ft.name, NamedTag{TagType: tagType, Name: ft.name, Payload: payload})
+	}
+	return compound, TAG_Compound, nil
+}
+
+// Unmarshal reflects over v (a pointer to a struct, map, slice, or primitive)
+// and populates it from tag, the way encoding/json.Unmarshal populates Go
+// values from JSON. See Marshal for the struct tag conventions.
+func Unmarshal(tag NamedTag, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("nbtparser: Unmarshal requires a non-nil pointer")
+	}
+	return unmarshalValue(tag.Payload, tag.TagType, rv.Elem())
+}
+
+func unmarshalValue(payload Tag, tagType TagType, rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Bool:
+		b, ok := payload.(byte)
+		if !ok {
+			return fmt.Errorf("nbtparser: cannot unmarshal %s into bool", typeToString[tagType])
+		}
+		rv.SetBool(b != 0)
+	case reflect.Int8:
+		b, ok := payload.(byte)
+		if !ok {
+			return fmt.Errorf("nbtparser: cannot unmarshal %s into int8", typeToString[tagType])
+		}
+		rv.SetInt(int64(int8(b)))
+	case reflect.Uint8:
+		b, ok := payload.(byte)
+		if !ok {
+			return fmt.Errorf("nbtparser: cannot unmarshal %s into uint8", typeToString[tagType])
+		}
+		rv.SetUint(uint64(b))
+	case reflect.Int16, reflect.Uint16:
+		v, ok := payload.(int16)
+		if !ok {
+			return fmt.Errorf("nbtparser: cannot unmarshal %s into %s", typeToString[tagType], rv.Kind())
+		}
+		if rv.Kind() == reflect.Uint16 {
+			rv.SetUint(uint64(uint16(v)))
+		} else {
+			rv.SetInt(int64(v))
+		}
+	case reflect.Int32, reflect.Int, reflect.Uint32, reflect.Uint:
+		v, ok := payload.(int32)
+		if !ok {
+			return fmt.Errorf("nbtparser: cannot unmarshal %s into %s", typeToString[tagType], rv.Kind())
+		}
+		if rv.Kind() == reflect.Uint32 || rv.Kind() == reflect.Uint {
+			rv.SetUint(uint64(uint32(v)))
+		} else {
+			rv.SetInt(int64(v))
+		}
+	case reflect.Int64, reflect.Uint64:
+		v, ok := payload.(int64)
+		if !ok {
+			return fmt.Errorf("nbtparser: cannot unmarshal %s into %s", typeToString[tagType], rv.Kind())
+		}
+		if rv.Kind() == reflect.Uint64 {
+			rv.SetUint(uint64(v))
+		} else {
+			rv.SetInt(v)
+		}
+	case reflect.Float32:
+		v, ok := payload.(float32)
+		if !ok {
+			return fmt.Errorf("nbtparser: cannot unmarshal %s into float32", typeToString[tagType])
+		}
+		rv.SetFloat(float64(v))
+	case reflect.Float64:
+		v, ok := payload.(float64)
+		if !ok {
+			return fmt.Errorf("nbtparser: cannot unmarshal %s into float64", typeToString[tagType])
+		}
+		rv.SetFloat(v)
+	case reflect.String:
+		v, ok := payload.(string)
+		if !ok {
+			return fmt.Errorf("nbtparser: cannot unmarshal %s into string", typeToString[tagType])
+		}
+		rv.SetString(v)
+	case reflect.Slice:
+		return unmarshalSlice(payload, tagType, rv)
+	case reflect.Map:
+		return unmarshalMap(payload, tagType, rv)
+	case reflect.Struct:
+		return unmarshalStruct(payload, tagType, rv)
+	case reflect.Ptr:
+		newVal := reflect.New(rv.Type().Elem())
+		if err := unmarshalValue(payload, tagType, newVal.Elem()); err != nil {
+			return err
+		}
+		rv.Set(newVal)
+	default:
+		return fmt.Errorf("nbtparser: cannot unmarshal into type %s", rv.Type())
+	}
+	return nil
+}
+
+func unmarshalSlice(payload Tag, tagType TagType, rv reflect.Value) error {
+	switch rv.Type().Elem().Kind() {
+	case reflect.Uint8:
+		data, ok := payload.([]byte)
+		if !ok {
+			return fmt.Errorf("nbtparser: cannot unmarshal %s into []byte", typeToString[tagType])
+		}
+		rv.SetBytes(append([]byte{}, data...))
+		return nil
+	case reflect.Int32:
+		data, ok := payload.([]int32)
+		if !ok {
+			return fmt.Errorf("nbtparser: cannot unmarshal %s into []int32", typeToString[tagType])
+		}
+		rv.Set(reflect.ValueOf(append([]int32{}, data...)))
+		return nil
+	case reflect.Int64:
+		data, ok := payload.([]int64)
+		if !ok {
+			return fmt.Errorf("nbtparser: cannot unmarshal %s into []int64", typeToString[tagType])
+		}
+		rv.Set(reflect.ValueOf(append([]int64{}, data...)))
+		return nil
+	}
+
+	list, ok := payload.(ListTag)
+	if !ok {
+		return fmt.Errorf("nbtparser: cannot unmarshal %s into %s", typeToString[tagType], rv.Type())
+	}
+	out := reflect.MakeSlice(rv.Type(), len(list.Elements), len(list.Elements))
+	for i, element := range list.Elements {
+		if err := unmarshalValue(element, list.TagType, out.Index(i)); err != nil {
+			return err
+		}
+	}
+	rv.Set(out)
+	return nil
+}
+
+func unmarshalMap(payload Tag, tagType TagType, rv reflect.Value) error {
+	if rv.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("nbtparser: cannot unmarshal into map with non-string key type %s", rv.Type().Key())
+	}
+	compound, ok := payload.(TagCompound)
+	if !ok {
+		return fmt.Errorf("nbtparser: cannot unmarshal %s into %s", typeToString[tagType], rv.Type())
+	}
+	m := reflect.MakeMapWithSize(rv.Type(), compound.Len())
+	for _, child := range compound.Entries() {
+		elem := reflect.New(rv.Type().Elem()).Elem()
+		if err := unmarshalValue(child.Payload, child.TagType, elem); err != nil {
+			return err
+		}
+		m.SetMapIndex(reflect.ValueOf(child.Name).Convert(rv.Type().Key()), elem)
+	}
+	rv.Set(m)
+	return nil
+}
+
+func unmarshalStruct(payload Tag, tagType TagType, rv reflect.Value) error {
+	compound, ok := payload.(TagCompound)
+	if !ok {
+		return fmt.Errorf("nbtparser: cannot unmarshal %s into %s", typeToString[tagType], rv.Type())
+	}
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		ft := parseFieldTag(field)
+		if ft.skip {
+			continue
+		}
+		child, ok := compound.Get(ft.name)
+		if !ok {
+			continue
+		}
+		if err := unmarshalValue(child.Payload, child.TagType, rv.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package nbtparser
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarshalSkipsNilPointerFieldsWithoutCorruptingSiblings(t *testing.T) {
+	type Inner struct {
+		V int32 `nbt:",int"`
+	}
+	type Outer struct {
+		A int32 `nbt:",int"`
+		B *Inner
+		C int32 `nbt:",int"`
+	}
+
+	tag, err := Marshal(Outer{A: 1, B: nil, C: 3})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteNBT(&buf, tag, false); err != nil {
+		t.Fatalf("WriteNBT failed: %v", err)
+	}
+
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	var out Outer
+	if err := Unmarshal(decoded, &out); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if out.A != 1 || out.C != 3 || out.B != nil {
+		t.Fatalf("got %+v; want A=1, B=nil, C=3 (C must not be silently dropped)", out)
+	}
+}
+
+func TestMarshalUnsignedFieldWithTypeHint(t *testing.T) {
+	type S struct {
+		V uint32 `nbt:",int"`
+	}
+
+	tag, err := Marshal(S{V: 5})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var out S
+	if err := Unmarshal(tag, &out); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if out.V != 5 {
+		t.Fatalf("got V=%d; want 5", out.V)
+	}
+}
+
+func TestMarshalNilDoesNotPanic(t *testing.T) {
+	if _, err := Marshal(nil); err == nil {
+		t.Fatal("expected an error marshaling untyped nil, got nil error")
+	}
+
+	var p *int
+	if _, err := Marshal(p); err == nil {
+		t.Fatal("expected an error marshaling a nil pointer, got nil error")
+	}
+}
@@ -0,0 +1,112 @@
+package nbtparser
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteDecodeRoundTrip(t *testing.T) {
+	compound := NewTagCompound()
+	compound.Set("A", NamedTag{TagType: TAG_Int, Name: "A", Payload: int32(1)})
+	compound.Set("B", NamedTag{TagType: TAG_String, Name: "B", Payload: "hello"})
+	compound.Set("C", NamedTag{TagType: TAG_Int, Name: "C", Payload: int32(3)})
+	original := NamedTag{TagType: TAG_Compound, Name: "root", Payload: compound}
+
+	var buf bytes.Buffer
+	if err := WriteNBT(&buf, original, false); err != nil {
+		t.Fatalf("WriteNBT failed: %v", err)
+	}
+
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	got := decoded.Payload.(TagCompound)
+	if got.Len() != 3 {
+		t.Fatalf("expected 3 entries, got %d", got.Len())
+	}
+	if a, ok := decoded.GetInt("A"); !ok || a != 1 {
+		t.Errorf("A = %v, %v; want 1, true", a, ok)
+	}
+	if b, ok := decoded.GetString("B"); !ok || b != "hello" {
+		t.Errorf("B = %q, %v; want \"hello\", true", b, ok)
+	}
+	if c, ok := decoded.GetInt("C"); !ok || c != 3 {
+		t.Errorf("C = %v, %v; want 3, true (not silently dropped)", c, ok)
+	}
+}
+
+func TestWriteNBTCompoundOrderIsDeterministic(t *testing.T) {
+	compound := NewTagCompound()
+	for _, key := range []string{"w", "x", "y", "z"} {
+		compound.Set(key, NamedTag{TagType: TAG_Byte, Name: key, Payload: byte(1)})
+	}
+	tag := NamedTag{TagType: TAG_Compound, Name: "", Payload: compound}
+
+	var first []byte
+	for i := 0; i < 10; i++ {
+		var buf bytes.Buffer
+		if err := WriteNBT(&buf, tag, false); err != nil {
+			t.Fatalf("WriteNBT failed: %v", err)
+		}
+		if i == 0 {
+			first = buf.Bytes()
+			continue
+		}
+		if !bytes.Equal(first, buf.Bytes()) {
+			t.Fatalf("WriteNBT produced different byte output across runs for the same compound")
+		}
+	}
+}
+
+func TestListTagConstructibleOutsidePackage(t *testing.T) {
+	list := NewListTag(TAG_Int, []Tag{int32(1), int32(2), int32(3)})
+	tag := NamedTag{TagType: TAG_List, Name: "nums", Payload: list}
+
+	var buf bytes.Buffer
+	if err := WriteNBT(&buf, tag, false); err != nil {
+		t.Fatalf("WriteNBT failed: %v", err)
+	}
+
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	got := decoded.Payload.(ListTag)
+	if got.TagType != TAG_Int || len(got.Elements) != 3 {
+		t.Fatalf("got %+v; want TagType=TAG_Int, 3 elements", got)
+	}
+}
+
+func TestDecodeZlibCompressedRoundTrip(t *testing.T) {
+	original := NamedTag{TagType: TAG_Byte, Name: "b", Payload: byte(7)}
+
+	var buf bytes.Buffer
+	if err := WriteZlibdNamedTag(&buf, original); err != nil {
+		t.Fatalf("WriteZlibdNamedTag failed: %v", err)
+	}
+
+	decoded, err := DecodeZlibCompressed(&buf)
+	if err != nil {
+		t.Fatalf("DecodeZlibCompressed failed: %v", err)
+	}
+	if decoded.Payload.(byte) != 7 {
+		t.Fatalf("got %v; want 7", decoded.Payload)
+	}
+}
+
+func TestTagByteArrayRejectsOversizedLength(t *testing.T) {
+	// A claimed TAG_Byte_Array length far larger than the data actually
+	// present must fail cleanly (EOF) instead of attempting a huge
+	// up-front allocation.
+	var buf bytes.Buffer
+	buf.WriteByte(byte(TAG_Byte_Array))
+	buf.Write([]byte{0, 0}) // empty name
+	buf.Write([]byte{0x7f, 0xff, 0xff, 0xff}) // length = MaxInt32
+
+	if _, err := Decode(&buf); err == nil {
+		t.Fatal("expected an error for an oversized TAG_Byte_Array length, got nil")
+	}
+}
@@ -1,36 +1,35 @@
 package nbtparser
 
 import (
-	"bytes"
+	"bufio"
 	"compress/gzip"
+	"compress/zlib"
 	"io"
-	"io/ioutil"
-	"log"
 )
 
-func decompress(w io.Writer, data []byte) error {
-	gr, err := gzip.NewReader(bytes.NewBuffer(data))
-	defer gr.Close()
-	data, err = ioutil.ReadAll(gr)
+// Decode reads a single NamedTag from r.
+func Decode(r io.Reader) (NamedTag, error) {
+	tagParseFuncsRef = tagParseFuncs
+	return parseNamedTag(bufio.NewReader(r))
+}
+
+// DecodeCompressed reads a gzip-compressed NamedTag from r.
+func DecodeCompressed(r io.Reader) (NamedTag, error) {
+	gr, err := gzip.NewReader(r)
 	if err != nil {
-		return err
+		return NamedTag{}, err
 	}
-	w.Write(data)
-	return nil
+	defer gr.Close()
+	return Decode(gr)
 }
 
-// ParseNBT Parse a NBT
-func ParseNBT(data []byte, isCompressed bool) NamedTag {
-	tagParseFuncsRef = tagParseFuncs
-	if isCompressed {
-		var decompressed bytes.Buffer
-		err := decompress(&decompressed, data)
-		if err != nil {
-			log.Fatal("Failed to decompress data: ", err)
-		}
-		tag, _ := parseNamedTag(decompressed.Bytes())
-		return tag
+// DecodeZlibCompressed reads a zlib-compressed NamedTag from r, the
+// counterpart to WriteZlibdNamedTag.
+func DecodeZlibCompressed(r io.Reader) (NamedTag, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return NamedTag{}, err
 	}
-	tag, _ := parseNamedTag(data)
-	return tag
+	defer zr.Close()
+	return Decode(zr)
 }
@@ -0,0 +1,415 @@
+package nbtparser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// identRe matches the characters SNBT allows in an unquoted string or key.
+var identRe = regexp.MustCompile(`^[A-Za-z0-9_.+-]+$`)
+
+// MarshalSNBT renders tag's payload as Mojang's stringified NBT (SNBT) format,
+// the text form used by command blocks and datapacks.
+func MarshalSNBT(tag NamedTag) (string, error) {
+	return writeSNBTValue(tag.Payload, tag.TagType)
+}
+
+func writeSNBTValue(payload Tag, tagType TagType) (string, error) {
+	switch tagType {
+	case TAG_End:
+		return "", nil
+	case TAG_Byte:
+		return fmt.Sprintf("%db", int8(payload.(byte))), nil
+	case TAG_Short:
+		return fmt.Sprintf("%ds", payload.(int16)), nil
+	case TAG_Int:
+		return fmt.Sprintf("%d", payload.(int32)), nil
+	case TAG_Long:
+		return fmt.Sprintf("%dL", payload.(int64)), nil
+	case TAG_Float:
+		return fmt.Sprintf("%gf", payload.(float32)), nil
+	case TAG_Double:
+		return fmt.Sprintf("%gd", payload.(float64)), nil
+	case TAG_String:
+		return quoteSNBTString(payload.(string)), nil
+	case TAG_Byte_Array:
+		data := payload.([]byte)
+		parts := make([]string, len(data))
+		for i, b := range data {
+			parts[i] = fmt.Sprintf("%d", int8(b))
+		}
+		return "[B;" + strings.Join(parts, ",") + "]", nil
+	case TAG_Int_Array:
+		data := payload.([]int32)
+		parts := make([]string, len(data))
+		for i, v := range data {
+			parts[i] = fmt.Sprintf("%d", v)
+		}
+		return "[I;" + strings.Join(parts, ",") + "]", nil
+	case TAG_Long_Array:
+		data := payload.([]int64)
+		parts := make([]string, len(data))
+		for i, v := range data {
+			parts[i] = fmt.Sprintf("%d", v)
+		}
+		return "[L;" + strings.Join(parts, ",") + "]", nil
+	case TAG_List:
+		list := payload.(ListTag)
+		parts := make([]string, len(list.Elements))
+		for i, element := range list.Elements {
+			s, err := writeSNBTValue(element, list.TagType)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = s
+		}
+		return "[" + strings.Join(parts, ",") + "]", nil
+	case TAG_Compound:
+		compound := payload.(TagCompound)
+		entries := compound.Entries()
+		parts := make([]string, len(entries))
+		for i, child := range entries {
+			valueStr, err := writeSNBTValue(child.Payload, child.TagType)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = formatSNBTKey(child.Name) + ":" + valueStr
+		}
+		return "{" + strings.Join(parts, ",") + "}", nil
+	default:
+		return "", fmt.Errorf("nbtparser: cannot marshal tag type %d to SNBT", tagType)
+	}
+}
+
+func formatSNBTKey(key string) string {
+	if identRe.MatchString(key) {
+		return key
+	}
+	return quoteSNBTString(key)
+}
+
+func quoteSNBTString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// UnmarshalSNBT parses a Mojang-style SNBT string into a NamedTag (with an empty name).
+func UnmarshalSNBT(s string) (NamedTag, error) {
+	p := &snbtParser{s: s}
+	p.skipWhitespace()
+	payload, tagType, err := p.parseValue()
+	if err != nil {
+		return NamedTag{}, err
+	}
+	p.skipWhitespace()
+	if p.pos != len(p.s) {
+		return NamedTag{}, fmt.Errorf("nbtparser: unexpected trailing data at offset %d", p.pos)
+	}
+	return NamedTag{TagType: tagType, Name: "", Payload: payload}, nil
+}
+
+type snbtParser struct {
+	s   string
+	pos int
+}
+
+func (p *snbtParser) skipWhitespace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\n' || p.s[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+func (p *snbtParser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *snbtParser) expect(c byte) error {
+	if p.peek() != c {
+		return fmt.Errorf("nbtparser: expected %q at offset %d", c, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *snbtParser) parseValue() (Tag, TagType, error) {
+	switch p.peek() {
+	case '{':
+		return p.parseCompound()
+	case '[':
+		return p.parseListOrArray()
+	case '"', '\'':
+		s, err := p.parseQuotedString()
+		return s, TAG_String, err
+	case 0:
+		return nil, 0, fmt.Errorf("nbtparser: unexpected end of input at offset %d", p.pos)
+	default:
+		return p.parseBareword()
+	}
+}
+
+func (p *snbtParser) parseQuotedString() (string, error) {
+	start := p.pos
+	quote := p.s[p.pos]
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.s) {
+			return "", fmt.Errorf("nbtparser: unterminated string starting at offset %d", start)
+		}
+		c := p.s[p.pos]
+		if c == '\\' && p.pos+1 < len(p.s) {
+			b.WriteByte(p.s[p.pos+1])
+			p.pos += 2
+			continue
+		}
+		if c == quote {
+			p.pos++
+			break
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	return b.String(), nil
+}
+
+func (p *snbtParser) parseBareword() (Tag, TagType, error) {
+	start := p.pos
+	for p.pos < len(p.s) && isBarewordByte(p.s[p.pos]) {
+		p.pos++
+	}
+	token := p.s[start:p.pos]
+	if token == "" {
+		return nil, 0, fmt.Errorf("nbtparser: unexpected character %q at offset %d", p.peek(), p.pos)
+	}
+	if payload, tagType, ok := parseNumberToken(token); ok {
+		return payload, tagType, nil
+	}
+	return token, TAG_String, nil
+}
+
+func isBarewordByte(c byte) bool {
+	return c == '_' || c == '.' || c == '+' || c == '-' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// parseNumberToken parses a number with an optional b/s/l/f/d type suffix.
+// ok is false when token isn't a valid SNBT number, so callers fall back to a bare string.
+func parseNumberToken(token string) (Tag, TagType, bool) {
+	body := token
+	var suffix byte
+	if n := len(token); n > 0 {
+		switch token[n-1] {
+		case 'b', 'B', 's', 'S', 'l', 'L', 'f', 'F', 'd', 'D':
+			suffix = token[n-1]
+			body = token[:n-1]
+		}
+	}
+	switch suffix {
+	case 'b', 'B':
+		v, err := strconv.ParseInt(body, 10, 8)
+		if err != nil {
+			return nil, 0, false
+		}
+		return byte(v), TAG_Byte, true
+	case 's', 'S':
+		v, err := strconv.ParseInt(body, 10, 16)
+		if err != nil {
+			return nil, 0, false
+		}
+		return int16(v), TAG_Short, true
+	case 'l', 'L':
+		v, err := strconv.ParseInt(body, 10, 64)
+		if err != nil {
+			return nil, 0, false
+		}
+		return v, TAG_Long, true
+	case 'f', 'F':
+		v, err := strconv.ParseFloat(body, 32)
+		if err != nil {
+			return nil, 0, false
+		}
+		return float32(v), TAG_Float, true
+	case 'd', 'D':
+		v, err := strconv.ParseFloat(body, 64)
+		if err != nil {
+			return nil, 0, false
+		}
+		return v, TAG_Double, true
+	}
+	if strings.Contains(body, ".") {
+		v, err := strconv.ParseFloat(body, 64)
+		if err != nil {
+			return nil, 0, false
+		}
+		return v, TAG_Double, true
+	}
+	v, err := strconv.ParseInt(body, 10, 32)
+	if err != nil {
+		return nil, 0, false
+	}
+	return int32(v), TAG_Int, true
+}
+
+func (p *snbtParser) parseKey() (string, error) {
+	if p.peek() == '"' || p.peek() == '\'' {
+		return p.parseQuotedString()
+	}
+	start := p.pos
+	for p.pos < len(p.s) && isBarewordByte(p.s[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("nbtparser: expected a compound key at offset %d", p.pos)
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *snbtParser) parseCompound() (Tag, TagType, error) {
+	if err := p.expect('{'); err != nil {
+		return nil, 0, err
+	}
+	compound := NewTagCompound()
+	p.skipWhitespace()
+	if p.peek() == '}' {
+		p.pos++
+		return compound, TAG_Compound, nil
+	}
+	for {
+		p.skipWhitespace()
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, 0, err
+		}
+		p.skipWhitespace()
+		if err := p.expect(':'); err != nil {
+			return nil, 0, err
+		}
+		p.skipWhitespace()
+		payload, tagType, err := p.parseValue()
+		if err != nil {
+			return nil, 0, err
+		}
+		compound.Set(key, NamedTag{TagType: tagType, Name: key, Payload: payload})
+		p.skipWhitespace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return compound, TAG_Compound, nil
+		default:
+			return nil, 0, fmt.Errorf("nbtparser: expected ',' or '}' at offset %d", p.pos)
+		}
+	}
+}
+
+func (p *snbtParser) parseListOrArray() (Tag, TagType, error) {
+	if err := p.expect('['); err != nil {
+		return nil, 0, err
+	}
+	if n := len(p.s); p.pos+1 < n && p.s[p.pos+1] == ';' {
+		switch p.s[p.pos] {
+		case 'B', 'I', 'L':
+			return p.parseArray(p.s[p.pos])
+		}
+	}
+	p.skipWhitespace()
+	if p.peek() == ']' {
+		p.pos++
+		return NewListTag(TAG_End, nil), TAG_List, nil
+	}
+	var elements []Tag
+	listType := TAG_End
+	for {
+		p.skipWhitespace()
+		payload, tagType, err := p.parseValue()
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(elements) == 0 {
+			listType = tagType
+		} else if tagType != listType {
+			return nil, 0, fmt.Errorf("nbtparser: list element of type %s does not match list type %s", typeToString[tagType], typeToString[listType])
+		}
+		elements = append(elements, payload)
+		p.skipWhitespace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return NewListTag(listType, elements), TAG_List, nil
+		default:
+			return nil, 0, fmt.Errorf("nbtparser: expected ',' or ']' at offset %d", p.pos)
+		}
+	}
+}
+
+func (p *snbtParser) parseArray(prefix byte) (Tag, TagType, error) {
+	p.pos += 2 // consume "X;"
+	p.skipWhitespace()
+	if p.peek() == ']' {
+		p.pos++
+		switch prefix {
+		case 'B':
+			return []byte{}, TAG_Byte_Array, nil
+		case 'I':
+			return []int32{}, TAG_Int_Array, nil
+		default:
+			return []int64{}, TAG_Long_Array, nil
+		}
+	}
+	var bytes []byte
+	var ints []int32
+	var longs []int64
+	for {
+		p.skipWhitespace()
+		start := p.pos
+		for p.pos < len(p.s) && isBarewordByte(p.s[p.pos]) {
+			p.pos++
+		}
+		token := p.s[start:p.pos]
+		v, err := strconv.ParseInt(strings.TrimRight(token, "bBsSlL"), 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("nbtparser: invalid array element %q at offset %d", token, start)
+		}
+		switch prefix {
+		case 'B':
+			bytes = append(bytes, byte(v))
+		case 'I':
+			ints = append(ints, int32(v))
+		default:
+			longs = append(longs, v)
+		}
+		p.skipWhitespace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			switch prefix {
+			case 'B':
+				return bytes, TAG_Byte_Array, nil
+			case 'I':
+				return ints, TAG_Int_Array, nil
+			default:
+				return longs, TAG_Long_Array, nil
+			}
+		default:
+			return nil, 0, fmt.Errorf("nbtparser: expected ',' or ']' at offset %d", p.pos)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package nbtparser
+
+import "testing"
+
+func TestSNBTRoundTrip(t *testing.T) {
+	compound := NewTagCompound()
+	compound.Set("name", NamedTag{TagType: TAG_String, Name: "name", Payload: "Steve"})
+	compound.Set("health", NamedTag{TagType: TAG_Float, Name: "health", Payload: float32(20)})
+	compound.Set("pos", NamedTag{TagType: TAG_Int_Array, Name: "pos", Payload: []int32{0, 64, 0}})
+	original := NamedTag{TagType: TAG_Compound, Name: "", Payload: compound}
+
+	str, err := MarshalSNBT(original)
+	if err != nil {
+		t.Fatalf("MarshalSNBT failed: %v", err)
+	}
+
+	parsed, err := UnmarshalSNBT(str)
+	if err != nil {
+		t.Fatalf("UnmarshalSNBT(%q) failed: %v", str, err)
+	}
+
+	if name, ok := parsed.GetString("name"); !ok || name != "Steve" {
+		t.Errorf("name = %q, %v; want \"Steve\", true", name, ok)
+	}
+	if health, ok := parsed.GetFloat("health"); !ok || health != 20 {
+		t.Errorf("health = %v, %v; want 20, true", health, ok)
+	}
+}
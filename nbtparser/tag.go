@@ -1,9 +1,11 @@
 package nbtparser
 
 import (
+	"bufio"
 	"encoding/binary"
 	"fmt"
-	"math"
+	"io"
+	"strings"
 )
 
 type TagType int
@@ -42,6 +44,14 @@ const (
 	//			have to be unique within each TAG_Compound
 	//          The order of the tags is not guaranteed.
 	TAG_Compound
+
+	// TAG_Int length
+	// An array of ints of unspecified format. The length of this array is <length> ints
+	TAG_Int_Array
+
+	// TAG_Int length
+	// An array of longs of unspecified format. The length of this array is <length> longs
+	TAG_Long_Array
 )
 
 var typeToString = map[TagType]string{
@@ -56,132 +66,346 @@ var typeToString = map[TagType]string{
 	TAG_String:     "TAG_String",
 	TAG_List:       "TAG_List",
 	TAG_Compound:   "TAG_Compound",
+	TAG_Int_Array:  "TAG_Int_Array",
+	TAG_Long_Array: "TAG_Long_Array",
 }
 
 type Tag interface{}
+
+// NamedTag is a single NBT tag: its type, name, and payload.
 type NamedTag struct {
-	tagType TagType
-	name    string
-	payload Tag
+	TagType TagType
+	Name    string
+	Payload Tag
+}
+
+// TagCompound is the payload of a TAG_Compound: its child tags keyed by name,
+// matching the spec's invariant that names are unique within a compound.
+// Insertion order is preserved (and recoverable via Keys/Entries) so that
+// WriteNBT and MarshalSNBT can round-trip a parsed compound byte-for-byte
+// instead of reordering its children at the whim of Go's map iteration.
+type TagCompound struct {
+	index   map[string]int
+	entries []NamedTag
+}
+
+// NewTagCompound returns an empty, ready-to-use TagCompound.
+func NewTagCompound() TagCompound {
+	return TagCompound{index: map[string]int{}}
+}
+
+// Get returns the child tag named key and whether it was present.
+func (c TagCompound) Get(key string) (NamedTag, bool) {
+	i, ok := c.index[key]
+	if !ok {
+		return NamedTag{}, false
+	}
+	return c.entries[i], true
+}
+
+// Set inserts or replaces the child tag named key, preserving its original
+// position on replace and appending on first insertion.
+func (c *TagCompound) Set(key string, tag NamedTag) {
+	if c.index == nil {
+		c.index = map[string]int{}
+	}
+	if i, ok := c.index[key]; ok {
+		c.entries[i] = tag
+		return
+	}
+	c.index[key] = len(c.entries)
+	c.entries = append(c.entries, tag)
+}
+
+// Len returns the number of child tags.
+func (c TagCompound) Len() int {
+	return len(c.entries)
+}
+
+// Keys returns the child tag names in insertion order.
+func (c TagCompound) Keys() []string {
+	keys := make([]string, len(c.entries))
+	for i, entry := range c.entries {
+		keys[i] = entry.Name
+	}
+	return keys
+}
+
+// Entries returns the child tags in insertion order.
+func (c TagCompound) Entries() []NamedTag {
+	return c.entries
 }
 
 func printUnnamedTag(buffer *string, prefix string, tag Tag, tagType TagType) {
 	*buffer += prefix + typeToString[tagType]
 	if tagType == TAG_Compound {
-		elements := tag.([]NamedTag)
-		*buffer += fmt.Sprintf(": %d entries\n%s{\n", len(elements), prefix)
-		for _, element := range elements {
+		elements := tag.(TagCompound)
+		*buffer += fmt.Sprintf(": %d entries\n%s{\n", elements.Len(), prefix)
+		for _, element := range elements.Entries() {
 			element.Print(buffer, "  "+prefix)
 		}
 		*buffer += prefix + "}\n"
 	} else if tagType == TAG_List {
 		tagList := tag.(ListTag)
-		*buffer += fmt.Sprintf(": %d entries of type %s\n%s{\n", len(tagList.elements), typeToString[tagList.listType], prefix)
-		for _, element := range tagList.elements {
-			printUnnamedTag(buffer, "  "+prefix, element, tagList.listType)
+		*buffer += fmt.Sprintf(": %d entries of type %s\n%s{\n", len(tagList.Elements), typeToString[tagList.TagType], prefix)
+		for _, element := range tagList.Elements {
+			printUnnamedTag(buffer, "  "+prefix, element, tagList.TagType)
 		}
 		*buffer += prefix + "}\n"
+	} else if tagType == TAG_Int_Array || tagType == TAG_Long_Array {
+		*buffer += fmt.Sprintf(": %s\n", formatArray(tag))
 	} else {
 		*buffer += fmt.Sprintf(": %v\n", tag)
 	}
 }
 
+// formatArray renders a TAG_Int_Array or TAG_Long_Array payload as comma-separated values.
+func formatArray(tag Tag) string {
+	switch array := tag.(type) {
+	case []int32:
+		values := make([]string, len(array))
+		for i, v := range array {
+			values[i] = fmt.Sprintf("%d", v)
+		}
+		return strings.Join(values, ", ")
+	case []int64:
+		values := make([]string, len(array))
+		for i, v := range array {
+			values[i] = fmt.Sprintf("%d", v)
+		}
+		return strings.Join(values, ", ")
+	default:
+		return fmt.Sprintf("%v", tag)
+	}
+}
+
 func (tag NamedTag) Print(buffer *string, prefix string) {
-	*buffer += prefix + typeToString[tag.tagType]
-	if tag.tagType == TAG_Compound {
-		elements := tag.payload.([]NamedTag)
-		*buffer += fmt.Sprintf("(\"%s\"): %d entries\n%s{\n", tag.name, len(elements), prefix)
-		for _, element := range elements {
+	*buffer += prefix + typeToString[tag.TagType]
+	if tag.TagType == TAG_Compound {
+		elements := tag.Payload.(TagCompound)
+		*buffer += fmt.Sprintf("(\"%s\"): %d entries\n%s{\n", tag.Name, elements.Len(), prefix)
+		for _, element := range elements.Entries() {
 			element.Print(buffer, "  "+prefix)
 		}
 		*buffer += prefix + "}\n"
-	} else if tag.tagType == TAG_List {
-		tagList := tag.payload.(ListTag)
-		*buffer += fmt.Sprintf("(\"%s\"): %d entries of type %s\n%s{\n", tag.name, len(tagList.elements), typeToString[tagList.listType], prefix)
-		for _, element := range tagList.elements {
-			printUnnamedTag(buffer, "  "+prefix, element, tagList.listType)
+	} else if tag.TagType == TAG_List {
+		tagList := tag.Payload.(ListTag)
+		*buffer += fmt.Sprintf("(\"%s\"): %d entries of type %s\n%s{\n", tag.Name, len(tagList.Elements), typeToString[tagList.TagType], prefix)
+		for _, element := range tagList.Elements {
+			printUnnamedTag(buffer, "  "+prefix, element, tagList.TagType)
 		}
 		*buffer += prefix + "}\n"
+	} else if tag.TagType == TAG_Int_Array || tag.TagType == TAG_Long_Array {
+		*buffer += fmt.Sprintf("(\"%s\"): %s\n", tag.Name, formatArray(tag.Payload))
 	} else {
-		*buffer += fmt.Sprintf("(\"%s\"): %v\n", tag.name, tag.payload)
+		*buffer += fmt.Sprintf("(\"%s\"): %v\n", tag.Name, tag.Payload)
 	}
 }
 
-var tagParseFuncsRef map[TagType](func([]byte) (Tag, uint)) // workaround to avoid initialization loop
-var tagParseFuncs = map[TagType](func([]byte) (Tag, uint)){
-	TAG_End:   func(payload []byte) (Tag, uint) { return nil, 0 },
-	TAG_Byte:  func(payload []byte) (Tag, uint) { return payload[0], 1 },
-	TAG_Short: func(payload []byte) (Tag, uint) { return int16(binary.BigEndian.Uint16(payload[0:2])), 2 },
-	TAG_Int:   func(payload []byte) (Tag, uint) { return int32(binary.BigEndian.Uint32(payload[0:4])), 4 },
-	TAG_Long:  func(payload []byte) (Tag, uint) { return int64(binary.BigEndian.Uint64(payload[0:8])), 8 },
-	TAG_Float: func(payload []byte) (Tag, uint) {
-		return math.Float32frombits(binary.BigEndian.Uint32(payload[0:4])), 4
+var tagParseFuncsRef map[TagType](func(*bufio.Reader) (Tag, error)) // workaround to avoid initialization loop
+var tagParseFuncs = map[TagType](func(*bufio.Reader) (Tag, error)){
+	TAG_End: func(r *bufio.Reader) (Tag, error) { return nil, nil },
+	TAG_Byte: func(r *bufio.Reader) (Tag, error) {
+		return r.ReadByte()
+	},
+	TAG_Short: func(r *bufio.Reader) (Tag, error) {
+		var v int16
+		err := binary.Read(r, binary.BigEndian, &v)
+		return v, err
+	},
+	TAG_Int: func(r *bufio.Reader) (Tag, error) {
+		var v int32
+		err := binary.Read(r, binary.BigEndian, &v)
+		return v, err
+	},
+	TAG_Long: func(r *bufio.Reader) (Tag, error) {
+		var v int64
+		err := binary.Read(r, binary.BigEndian, &v)
+		return v, err
+	},
+	TAG_Float: func(r *bufio.Reader) (Tag, error) {
+		var v float32
+		err := binary.Read(r, binary.BigEndian, &v)
+		return v, err
 	},
-	TAG_Double: func(payload []byte) (Tag, uint) {
-		return math.Float64frombits(binary.BigEndian.Uint64(payload[0:8])), 8
+	TAG_Double: func(r *bufio.Reader) (Tag, error) {
+		var v float64
+		err := binary.Read(r, binary.BigEndian, &v)
+		return v, err
 	},
-	TAG_Byte_Array: func(payload []byte) (Tag, uint) {
-		length := binary.BigEndian.Uint32(payload[0:4])
-		return payload[4 : 4+length], uint(4 + length)
+	TAG_Byte_Array: func(r *bufio.Reader) (Tag, error) {
+		var length int32
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, fmt.Errorf("nbtparser: invalid TAG_Byte_Array length %d", length)
+		}
+		// Grow incrementally rather than make([]byte, length) up front: a
+		// crafted/truncated file can claim an arbitrarily large length, and
+		// allocating for it before reading a single byte is an easy OOM.
+		payload := make([]byte, 0, minInt(int(length), maxArrayPrealloc))
+		buf := make([]byte, maxArrayChunk)
+		for remaining := int(length); remaining > 0; {
+			n := minInt(remaining, maxArrayChunk)
+			if _, err := io.ReadFull(r, buf[:n]); err != nil {
+				return nil, err
+			}
+			payload = append(payload, buf[:n]...)
+			remaining -= n
+		}
+		return payload, nil
 	},
-	TAG_String: func(payload []byte) (Tag, uint) {
-		length := binary.BigEndian.Uint16(payload[0:2])
-		return string(payload[2 : 2+length]), uint(2 + length)
+	TAG_String: func(r *bufio.Reader) (Tag, error) {
+		var length uint16
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return nil, err
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return nil, err
+		}
+		return string(payload), nil
 	},
 	TAG_Compound: parseCompoundTag,
 	TAG_List:     parseListTag,
+	TAG_Int_Array: func(r *bufio.Reader) (Tag, error) {
+		var length int32
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, fmt.Errorf("nbtparser: invalid TAG_Int_Array length %d", length)
+		}
+		ret := make([]int32, 0, minInt(int(length), maxArrayPrealloc))
+		for i := int32(0); i < length; i++ {
+			var v int32
+			if err := binary.Read(r, binary.BigEndian, &v); err != nil {
+				return nil, err
+			}
+			ret = append(ret, v)
+		}
+		return ret, nil
+	},
+	TAG_Long_Array: func(r *bufio.Reader) (Tag, error) {
+		var length int32
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, fmt.Errorf("nbtparser: invalid TAG_Long_Array length %d", length)
+		}
+		ret := make([]int64, 0, minInt(int(length), maxArrayPrealloc))
+		for i := int32(0); i < length; i++ {
+			var v int64
+			if err := binary.Read(r, binary.BigEndian, &v); err != nil {
+				return nil, err
+			}
+			ret = append(ret, v)
+		}
+		return ret, nil
+	},
+}
+
+// maxArrayChunk bounds how many bytes TAG_Byte_Array reads per iteration, and
+// maxArrayPrealloc bounds how much capacity any array payload preallocates up
+// front, so a maliciously large claimed length fails on read instead of
+// triggering an immediate multi-gigabyte allocation.
+const (
+	maxArrayChunk    = 64 * 1024
+	maxArrayPrealloc = 1 << 20
+)
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
 }
 
-func parseCompoundTag(payload []byte) (Tag, uint) { // Payload: []NamedTag
-	var ret []NamedTag
-	var current uint
+func parseCompoundTag(r *bufio.Reader) (Tag, error) { // Payload: TagCompound
+	ret := NewTagCompound()
 	for {
-		tag, length := parseNamedTag(payload[current:])
-		current += length
-		if tag.tagType == TAG_End {
+		tag, err := parseNamedTag(r)
+		if err != nil {
+			return nil, err
+		}
+		if tag.TagType == TAG_End {
 			break
 		}
-		ret = append(ret, tag)
+		ret.Set(tag.Name, tag)
 	}
-	return ret, current
+	return ret, nil
 }
 
+// ListTag is the payload of a TAG_List: a sequence of same-typed,
+// unnamed tags.
 type ListTag struct {
-	listType TagType
-	elements []Tag
+	TagType  TagType
+	Elements []Tag
+}
+
+// NewListTag returns a ListTag holding elements, all of type tagType.
+func NewListTag(tagType TagType, elements []Tag) ListTag {
+	return ListTag{TagType: tagType, Elements: elements}
 }
 
-func parseListTag(payload []byte) (Tag, uint) { // Payload: []NamedTag
+func parseListTag(r *bufio.Reader) (Tag, error) { // Payload: ListTag
+	tagTypeByte, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	tagType := TagType(tagTypeByte)
+	parseFunc, ok := tagParseFuncsRef[tagType]
+	if !ok {
+		return nil, fmt.Errorf("nbtparser: unknown tag type %d in TAG_List", tagType)
+	}
+	var length int32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("nbtparser: invalid TAG_List length %d", length)
+	}
 	var ret []Tag
-	tagType := TagType(payload[0])
-	length := binary.BigEndian.Uint32(payload[1:5])
-	var current uint = 5
-	for i := 0; i < int(length); i++ {
-		tag, length := tagParseFuncsRef[tagType](payload[current:])
-		current += length
+	for i := int32(0); i < length; i++ {
+		tag, err := parseFunc(r)
+		if err != nil {
+			return nil, err
+		}
 		ret = append(ret, tag)
 	}
-	return ListTag{tagType, ret}, current
+	return NewListTag(tagType, ret), nil
 }
 
-func parseNamedTag(data []byte) (NamedTag, uint) {
+func parseNamedTag(r *bufio.Reader) (NamedTag, error) {
 	// A Named Tag has the following format:
 	// byte tagType
 	// TAG_String name
 	// [payload]
 	var namedTag NamedTag
-	var nameLength, payloadStart uint16
-	namedTag.tagType = TagType(data[0])
-	if namedTag.tagType != TAG_End {
-		nameLength = binary.BigEndian.Uint16(data[1:3])
-		namedTag.name = string(data[3 : 3+nameLength])
-		payloadStart = 3 + nameLength
+	tagTypeByte, err := r.ReadByte()
+	if err != nil {
+		return namedTag, err
+	}
+	namedTag.TagType = TagType(tagTypeByte)
+	if namedTag.TagType != TAG_End {
+		var nameLength uint16
+		if err := binary.Read(r, binary.BigEndian, &nameLength); err != nil {
+			return namedTag, err
+		}
+		nameBytes := make([]byte, nameLength)
+		if _, err := io.ReadFull(r, nameBytes); err != nil {
+			return namedTag, err
+		}
+		namedTag.Name = string(nameBytes)
 	} else {
-		nameLength = 0
-		namedTag.name = "" // The name is assumed to be "" in case of TAG_End
-		payloadStart = 1
+		namedTag.Name = "" // The name is assumed to be "" in case of TAG_End
+	}
+	parseFunc, ok := tagParseFuncsRef[namedTag.TagType]
+	if !ok {
+		return namedTag, fmt.Errorf("nbtparser: unknown tag type %d", namedTag.TagType)
 	}
-	var payloadLength uint
-	namedTag.payload, payloadLength = tagParseFuncsRef[namedTag.tagType](data[payloadStart:])
-	return namedTag, uint(uint(payloadStart) + payloadLength)
+	namedTag.Payload, err = parseFunc(r)
+	return namedTag, err
 }
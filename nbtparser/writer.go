@@ -0,0 +1,131 @@
+package nbtparser
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+)
+
+var tagWriteFuncsRef map[TagType](func(w io.Writer, tag Tag) error) // workaround to avoid initialization loop
+var tagWriteFuncs = map[TagType](func(w io.Writer, tag Tag) error){
+	TAG_End:   func(w io.Writer, tag Tag) error { return nil },
+	TAG_Byte:  func(w io.Writer, tag Tag) error { return binary.Write(w, binary.BigEndian, tag.(byte)) },
+	TAG_Short: func(w io.Writer, tag Tag) error { return binary.Write(w, binary.BigEndian, tag.(int16)) },
+	TAG_Int:   func(w io.Writer, tag Tag) error { return binary.Write(w, binary.BigEndian, tag.(int32)) },
+	TAG_Long:  func(w io.Writer, tag Tag) error { return binary.Write(w, binary.BigEndian, tag.(int64)) },
+	TAG_Float: func(w io.Writer, tag Tag) error { return binary.Write(w, binary.BigEndian, tag.(float32)) },
+	TAG_Double: func(w io.Writer, tag Tag) error {
+		return binary.Write(w, binary.BigEndian, tag.(float64))
+	},
+	TAG_Byte_Array: func(w io.Writer, tag Tag) error {
+		payload := tag.([]byte)
+		if err := binary.Write(w, binary.BigEndian, int32(len(payload))); err != nil {
+			return err
+		}
+		_, err := w.Write(payload)
+		return err
+	},
+	TAG_String: func(w io.Writer, tag Tag) error {
+		payload := tag.(string)
+		if err := binary.Write(w, binary.BigEndian, uint16(len(payload))); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, payload)
+		return err
+	},
+	TAG_Compound: writeCompoundTag,
+	TAG_List:     writeListTag,
+	TAG_Int_Array: func(w io.Writer, tag Tag) error {
+		payload := tag.([]int32)
+		if err := binary.Write(w, binary.BigEndian, int32(len(payload))); err != nil {
+			return err
+		}
+		return binary.Write(w, binary.BigEndian, payload)
+	},
+	TAG_Long_Array: func(w io.Writer, tag Tag) error {
+		payload := tag.([]int64)
+		if err := binary.Write(w, binary.BigEndian, int32(len(payload))); err != nil {
+			return err
+		}
+		return binary.Write(w, binary.BigEndian, payload)
+	},
+}
+
+func writeCompoundTag(w io.Writer, tag Tag) error { // Payload: TagCompound
+	elements := tag.(TagCompound)
+	for _, element := range elements.Entries() {
+		if err := writeNamedTag(w, element); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write([]byte{byte(TAG_End)})
+	return err
+}
+
+func writeListTag(w io.Writer, tag Tag) error { // Payload: ListTag
+	list := tag.(ListTag)
+	if _, err := w.Write([]byte{byte(list.TagType)}); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, int32(len(list.Elements))); err != nil {
+		return err
+	}
+	for _, element := range list.Elements {
+		if err := tagWriteFuncsRef[list.TagType](w, element); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeNamedTag(w io.Writer, tag NamedTag) error {
+	// A Named Tag has the following format:
+	// byte tagType
+	// TAG_String name
+	// [payload]
+	if _, err := w.Write([]byte{byte(tag.TagType)}); err != nil {
+		return err
+	}
+	if tag.TagType == TAG_End {
+		return nil
+	}
+	if err := binary.Write(w, binary.BigEndian, uint16(len(tag.Name))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, tag.Name); err != nil {
+		return err
+	}
+	return tagWriteFuncsRef[tag.TagType](w, tag.Payload)
+}
+
+// WriteNBT serializes tag to w, gzip-compressing the output when compress is true.
+func WriteNBT(w io.Writer, tag NamedTag, compress bool) error {
+	tagWriteFuncsRef = tagWriteFuncs
+	if !compress {
+		return writeNamedTag(w, tag)
+	}
+	return WriteGzipdNamedTag(w, tag)
+}
+
+// WriteGzipdNamedTag serializes tag to w as a gzip-compressed stream.
+func WriteGzipdNamedTag(w io.Writer, tag NamedTag) error {
+	tagWriteFuncsRef = tagWriteFuncs
+	gw := gzip.NewWriter(w)
+	if err := writeNamedTag(gw, tag); err != nil {
+		gw.Close()
+		return err
+	}
+	return gw.Close()
+}
+
+// WriteZlibdNamedTag serializes tag to w as a zlib-compressed stream.
+func WriteZlibdNamedTag(w io.Writer, tag NamedTag) error {
+	tagWriteFuncsRef = tagWriteFuncs
+	zw := zlib.NewWriter(w)
+	if err := writeNamedTag(zw, tag); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}